@@ -0,0 +1,30 @@
+// Package appLogValidator checks whether a blob of `cf logs` output
+// contains the evidence a log-based measurement is looking for.
+package appLogValidator
+
+import "strings"
+
+// AppLogValidator validates a chunk of log output against an expected
+// correlation ID.
+type AppLogValidator interface {
+	// Validate reports whether output contains correlationID. If
+	// correlationID is empty, it instead reports whether output is
+	// non-empty, for measurements that only care that logs arrived at
+	// all rather than that a specific line did.
+	Validate(output, correlationID string) bool
+}
+
+type appLogValidator struct{}
+
+// New builds the default AppLogValidator.
+func New() AppLogValidator {
+	return &appLogValidator{}
+}
+
+func (v *appLogValidator) Validate(output, correlationID string) bool {
+	if correlationID == "" {
+		return strings.TrimSpace(output) != ""
+	}
+
+	return strings.Contains(output, correlationID)
+}
@@ -0,0 +1,145 @@
+package artifacts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Manager periodically sweeps a root directory and hands every file it
+// finds off to a fixed-size pool of workers for upload, so long runs flush
+// artifacts incrementally rather than only at teardown.
+type Manager struct {
+	rootDir       string
+	uploader      Uploader
+	sweepInterval time.Duration
+	workerCount   int
+
+	mu     sync.Mutex
+	sent   map[string]bool
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewManager builds a Manager that sweeps rootDir every sweepInterval,
+// uploading new files via uploader using workerCount concurrent workers.
+func NewManager(rootDir string, uploader Uploader, sweepInterval time.Duration, workerCount int) *Manager {
+	return &Manager{
+		rootDir:       rootDir,
+		uploader:      uploader,
+		sweepInterval: sweepInterval,
+		workerCount:   workerCount,
+		sent:          map[string]bool{},
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+}
+
+// Start begins the periodic sweep in the background. Call Stop to end it.
+func (m *Manager) Start(ctx context.Context) {
+	go func() {
+		defer close(m.doneCh)
+
+		ticker := time.NewTicker(m.sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.sweep(ctx)
+			case <-m.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic sweep and blocks until it has exited.
+func (m *Manager) Stop() {
+	close(m.stopCh)
+	<-m.doneCh
+}
+
+// FlushAll performs one final, synchronous sweep, uploading anything left
+// in rootDir. It's meant to be called from teardown, after the last
+// result JSON has been written.
+func (m *Manager) FlushAll(ctx context.Context) error {
+	return m.sweep(ctx)
+}
+
+func (m *Manager) sweep(ctx context.Context) error {
+	var paths []string
+	err := filepath.Walk(m.rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		m.mu.Lock()
+		alreadySent := m.sent[path]
+		m.mu.Unlock()
+		if !alreadySent {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	errs := make(chan error, len(paths))
+
+	for i := 0; i < m.workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				if err := m.upload(ctx, path); err != nil {
+					errs <- err
+					continue
+				}
+
+				m.mu.Lock()
+				m.sent[path] = true
+				m.mu.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Manager) upload(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	key, err := filepath.Rel(m.rootDir, path)
+	if err != nil {
+		key = filepath.Base(path)
+	}
+
+	return m.uploader.Upload(ctx, key, f)
+}
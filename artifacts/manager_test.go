@@ -0,0 +1,141 @@
+package artifacts
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeUploader struct {
+	mu       sync.Mutex
+	uploaded map[string]string
+	failKeys map[string]bool
+	calls    int
+}
+
+func newFakeUploader(failKeys ...string) *fakeUploader {
+	fail := map[string]bool{}
+	for _, k := range failKeys {
+		fail[k] = true
+	}
+
+	return &fakeUploader{uploaded: map[string]string{}, failKeys: fail}
+}
+
+func (f *fakeUploader) Upload(ctx context.Context, key string, body io.Reader) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.failKeys[key] {
+		return errUploadFailed
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	f.uploaded[key] = string(data)
+
+	return nil
+}
+
+var errUploadFailed = &uploadError{"simulated upload failure"}
+
+type uploadError struct{ msg string }
+
+func (e *uploadError) Error() string { return e.msg }
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+}
+
+func TestFlushAllUploadsEveryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "stdout.log", "out")
+	writeFile(t, dir, "stderr.log", "err")
+
+	uploader := newFakeUploader()
+	m := NewManager(dir, uploader, time.Minute, 2)
+
+	if err := m.FlushAll(context.Background()); err != nil {
+		t.Fatalf("FlushAll returned error: %v", err)
+	}
+
+	if uploader.uploaded["stdout.log"] != "out" || uploader.uploaded["stderr.log"] != "err" {
+		t.Errorf("expected both files uploaded, got %#v", uploader.uploaded)
+	}
+}
+
+func TestFlushAllDoesNotReuploadAlreadySentFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "stdout.log", "out")
+
+	uploader := newFakeUploader()
+	m := NewManager(dir, uploader, time.Minute, 2)
+
+	if err := m.FlushAll(context.Background()); err != nil {
+		t.Fatalf("first FlushAll returned error: %v", err)
+	}
+	if err := m.FlushAll(context.Background()); err != nil {
+		t.Fatalf("second FlushAll returned error: %v", err)
+	}
+
+	if uploader.calls != 1 {
+		t.Errorf("expected exactly one upload call, got %d", uploader.calls)
+	}
+}
+
+func TestFlushAllRetriesOnlyFailedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "ok.log", "fine")
+	writeFile(t, dir, "bad.log", "broken")
+
+	uploader := newFakeUploader("bad.log")
+	m := NewManager(dir, uploader, time.Minute, 2)
+
+	if err := m.FlushAll(context.Background()); err == nil {
+		t.Fatal("expected first FlushAll to return the bad.log upload error")
+	}
+
+	if _, ok := uploader.uploaded["ok.log"]; !ok {
+		t.Fatal("expected ok.log to have been uploaded despite bad.log failing")
+	}
+
+	callsAfterFirstSweep := uploader.calls
+
+	uploader.failKeys["bad.log"] = false
+	if err := m.FlushAll(context.Background()); err != nil {
+		t.Fatalf("second FlushAll returned error: %v", err)
+	}
+
+	if uploader.calls != callsAfterFirstSweep+1 {
+		t.Errorf("expected exactly one more upload call (retrying bad.log only), got %d more", uploader.calls-callsAfterFirstSweep)
+	}
+	if _, ok := uploader.uploaded["bad.log"]; !ok {
+		t.Error("expected bad.log to be uploaded once it stopped failing")
+	}
+}
@@ -0,0 +1,131 @@
+// Package artifacts uploads the files a run leaves in its artifacts
+// directory (its buffered command output) to object storage, so a failed
+// CF upgrade can be investigated after the uptimer process has exited.
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"cloud.google.com/go/storage"
+)
+
+// Uploader pushes a single named artifact to a destination. Implementations
+// are provided for S3-compatible object storage, GCS, and the local
+// filesystem, so a run can be configured to ship artifacts wherever the
+// operator already keeps them.
+type Uploader interface {
+	Upload(ctx context.Context, key string, body io.Reader) error
+}
+
+// S3Uploader uploads artifacts to an S3-compatible bucket.
+type S3Uploader struct {
+	bucket string
+	prefix string
+	client *s3manager.Uploader
+}
+
+// NewS3Uploader builds an S3Uploader for the given bucket and region.
+// Credentials are taken from the environment, per the AWS SDK's default
+// credential chain.
+func NewS3Uploader(bucket, region, prefix string) (*S3Uploader, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(region)})
+	if err != nil {
+		return nil, fmt.Errorf("creating S3 session: %w", err)
+	}
+
+	return &S3Uploader{
+		bucket: bucket,
+		prefix: prefix,
+		client: s3manager.NewUploader(sess),
+	}, nil
+}
+
+// Upload implements Uploader.
+func (u *S3Uploader) Upload(ctx context.Context, key string, body io.Reader) error {
+	_, err := u.client.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(filepath.Join(u.prefix, key)),
+		Body:   body,
+	})
+	if err != nil {
+		return fmt.Errorf("uploading %s to s3://%s: %w", key, u.bucket, err)
+	}
+
+	return nil
+}
+
+// GCSUploader uploads artifacts to a Google Cloud Storage bucket.
+type GCSUploader struct {
+	bucket string
+	prefix string
+	client *storage.Client
+}
+
+// NewGCSUploader builds a GCSUploader for the given bucket, authenticating
+// with application default credentials.
+func NewGCSUploader(ctx context.Context, bucket, prefix string) (*GCSUploader, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCS client: %w", err)
+	}
+
+	return &GCSUploader{bucket: bucket, prefix: prefix, client: client}, nil
+}
+
+// Upload implements Uploader.
+func (u *GCSUploader) Upload(ctx context.Context, key string, body io.Reader) error {
+	w := u.client.Bucket(u.bucket).Object(filepath.Join(u.prefix, key)).NewWriter(ctx)
+	if _, err := io.Copy(w, body); err != nil {
+		w.Close()
+		return fmt.Errorf("uploading %s to gs://%s: %w", key, u.bucket, err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("uploading %s to gs://%s: %w", key, u.bucket, err)
+	}
+
+	return nil
+}
+
+// LocalUploader copies artifacts into a directory on the local filesystem,
+// for development and for operators without object storage configured.
+type LocalUploader struct {
+	rootDir string
+}
+
+// NewLocalUploader builds a LocalUploader rooted at dir, creating it if it
+// doesn't already exist.
+func NewLocalUploader(dir string) (*LocalUploader, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating local artifact dir %s: %w", dir, err)
+	}
+
+	return &LocalUploader{rootDir: dir}, nil
+}
+
+// Upload implements Uploader.
+func (u *LocalUploader) Upload(ctx context.Context, key string, body io.Reader) error {
+	dest := filepath.Join(u.rootDir, key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("creating local artifact dir for %s: %w", key, err)
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("reading artifact %s: %w", key, err)
+	}
+
+	if err := ioutil.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("writing local artifact %s: %w", dest, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+// Package cfCmdGenerator builds the individual `cf` CLI invocations that
+// cfWorkflow composes into setup, push, and teardown sequences.
+package cfCmdGenerator
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// CfCmdGenerator builds `cf` commands scoped to their own CF_HOME, so
+// concurrent workflows never trample each other's target or session
+// state.
+type CfCmdGenerator struct {
+	cfHomeDir string
+}
+
+// New builds a CfCmdGenerator whose commands use cfHomeDir as CF_HOME.
+func New(cfHomeDir string) CfCmdGenerator {
+	return CfCmdGenerator{cfHomeDir: cfHomeDir}
+}
+
+func (c CfCmdGenerator) cf(args ...string) cmdStartWaiter.CmdStartWaiter {
+	cmd := exec.Command("cf", args...)
+	cmd.Env = append(cmd.Env, "CF_HOME="+c.cfHomeDir)
+
+	return cmdStartWaiter.Wrap(cmd)
+}
+
+// Api targets a CF API endpoint.
+func (c CfCmdGenerator) Api(api string, skipSSLValidation bool) cmdStartWaiter.CmdStartWaiter {
+	args := []string{"api", api}
+	if skipSSLValidation {
+		args = append(args, "--skip-ssl-validation")
+	}
+
+	return c.cf(args...)
+}
+
+// Auth logs in with a username and password.
+func (c CfCmdGenerator) Auth(user, password string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("auth", user, password)
+}
+
+// CreateOrg creates an org.
+func (c CfCmdGenerator) CreateOrg(org string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("create-org", org)
+}
+
+// CreateSpace creates a space within an org.
+func (c CfCmdGenerator) CreateSpace(org, space string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("create-space", "-o", org, space)
+}
+
+// Target targets an org and space.
+func (c CfCmdGenerator) Target(org, space string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("target", "-o", org, "-s", space)
+}
+
+// CreateQuota creates a quota definition sized for a single measurement
+// app.
+func (c CfCmdGenerator) CreateQuota(quota string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("create-quota", quota, "-m", "10G", "-i", "1G", "-r", "10")
+}
+
+// SetQuota assigns a quota to an org.
+func (c CfCmdGenerator) SetQuota(org, quota string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("set-quota", org, quota)
+}
+
+// Push pushes an app without starting it.
+func (c CfCmdGenerator) Push(appName, appDir, command string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("push", appName, "-p", appDir, "-c", command, "--no-start")
+}
+
+// Start starts a previously pushed app.
+func (c CfCmdGenerator) Start(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("start", appName)
+}
+
+// Restage restages an app, picking up any newly bound services.
+func (c CfCmdGenerator) Restage(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("restage", appName)
+}
+
+// Delete deletes an app and its routes.
+func (c CfCmdGenerator) Delete(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("delete", appName, "-f", "-r")
+}
+
+// MapRoute maps a route on domain to an app.
+func (c CfCmdGenerator) MapRoute(appName, domain string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("map-route", appName, domain)
+}
+
+// CreateUserProvidedService registers a syslog drain URL as a
+// user-provided service.
+func (c CfCmdGenerator) CreateUserProvidedService(serviceName, syslogDrainURL string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("create-user-provided-service", serviceName, "-l", syslogDrainURL)
+}
+
+// BindService binds a service to an app.
+func (c CfCmdGenerator) BindService(appName, serviceName string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("bind-service", appName, serviceName)
+}
+
+// Ssh runs a single command on an app's container over `cf ssh`.
+func (c CfCmdGenerator) Ssh(appName, command string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("ssh", appName, "-c", command)
+}
+
+// RecentLogs fetches an app's recent logs.
+func (c CfCmdGenerator) RecentLogs(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("logs", "--recent", appName)
+}
+
+// Logs tails an app's logs until the command is canceled.
+func (c CfCmdGenerator) Logs(appName string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("logs", appName)
+}
+
+// LogsContext tails an app's logs, killing the tail when ctx is done
+// rather than leaving it running for the lifetime of the process.
+func (c CfCmdGenerator) LogsContext(ctx context.Context, appName string) cmdStartWaiter.CmdStartWaiter {
+	cmd := exec.CommandContext(ctx, "cf", "logs", appName)
+	cmd.Env = append(cmd.Env, "CF_HOME="+c.cfHomeDir)
+
+	return cmdStartWaiter.Wrap(cmd)
+}
+
+// Org inspects a single org, including its assigned quota.
+func (c CfCmdGenerator) Org(org string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("org", org)
+}
+
+// Orgs lists every org visible to the logged-in user.
+func (c CfCmdGenerator) Orgs() cmdStartWaiter.CmdStartWaiter {
+	return c.cf("orgs")
+}
+
+// DeleteOrg deletes an org and everything in it.
+func (c CfCmdGenerator) DeleteOrg(org string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("delete-org", org, "-f")
+}
+
+// DeleteQuota deletes a quota definition.
+func (c CfCmdGenerator) DeleteQuota(quota string) cmdStartWaiter.CmdStartWaiter {
+	return c.cf("delete-quota", quota, "-f")
+}
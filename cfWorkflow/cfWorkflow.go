@@ -0,0 +1,162 @@
+// Package cfWorkflow composes cfCmdGenerator's individual `cf` commands
+// into the setup, push, route, log, and teardown sequences a measurement
+// run needs against one org/space/app.
+package cfWorkflow
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	"github.com/cloudfoundry/uptimer/config"
+)
+
+// syslogDrainServiceName is the user-provided service CreateAndBindSyslogDrain
+// registers and binds to this workflow's app.
+const syslogDrainServiceName = "uptimer-syslog-drain"
+
+// CfWorkflow builds the command sequences for one org/space/app: standing
+// it up, pushing and routing its app, reading its logs, and tearing it
+// back down.
+type CfWorkflow interface {
+	Setup(cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	Push(cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	Delete(cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	MapRoute(cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	TearDown(cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	RecentLogs(cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	StreamLogs(ctx context.Context, cmdGenerator cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter
+	CreateAndBindSyslogDrain(sinkRoute string) []cmdStartWaiter.CmdStartWaiter
+	RestageApp() []cmdStartWaiter.CmdStartWaiter
+	EmitLog(cmdGenerator cfCmdGenerator.CfCmdGenerator, tag string) []cmdStartWaiter.CmdStartWaiter
+	AppUrl() string
+}
+
+type cfWorkflow struct {
+	cfc *config.Cf
+
+	org, space, quota string
+	appName           string
+	appPath           string
+	appCommand        string
+
+	// cfHomeDir backs defaultCmdGenerator, the CfCmdGenerator used for
+	// commands that aren't tied to a caller-supplied one - namely the
+	// syslog drain service create/bind and the restage it requires.
+	cfHomeDir string
+}
+
+// New builds a CfWorkflow for one org/space/app, identified by the names
+// the caller generated for this run.
+func New(cfc *config.Cf, org, space, quota, appName, appPath, appCommand string) CfWorkflow {
+	return &cfWorkflow{
+		cfc:        cfc,
+		org:        org,
+		space:      space,
+		quota:      quota,
+		appName:    appName,
+		appPath:    appPath,
+		appCommand: appCommand,
+	}
+}
+
+// Setup implements CfWorkflow. It targets the API, logs in as the admin
+// user, and creates and targets this run's org, space, and quota.
+func (w *cfWorkflow) Setup(g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		g.Api(w.cfc.API, true),
+		g.Auth(w.cfc.AdminUser, w.cfc.AdminPassword),
+		g.CreateQuota(w.quota),
+		g.CreateOrg(w.org),
+		g.SetQuota(w.org, w.quota),
+		g.CreateSpace(w.org, w.space),
+		g.Target(w.org, w.space),
+	}
+}
+
+// Push implements CfWorkflow.
+func (w *cfWorkflow) Push(g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		g.Push(w.appName, w.appPath, w.appCommand),
+		g.Start(w.appName),
+	}
+}
+
+// Delete implements CfWorkflow.
+func (w *cfWorkflow) Delete(g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{g.Delete(w.appName)}
+}
+
+// MapRoute implements CfWorkflow.
+func (w *cfWorkflow) MapRoute(g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{g.MapRoute(w.appName, w.cfc.AppDomain)}
+}
+
+// TearDown implements CfWorkflow. It deletes the org and the quota
+// definition created for it; quota definitions are a separate CC API
+// object that deleting the org does not remove.
+func (w *cfWorkflow) TearDown(g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{
+		g.DeleteOrg(w.org),
+		g.DeleteQuota(w.quota),
+	}
+}
+
+// RecentLogs implements CfWorkflow.
+func (w *cfWorkflow) RecentLogs(g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{g.RecentLogs(w.appName)}
+}
+
+// StreamLogs implements CfWorkflow. The returned command is tied to ctx,
+// so canceling ctx ends the tail.
+func (w *cfWorkflow) StreamLogs(ctx context.Context, g cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{g.LogsContext(ctx, w.appName)}
+}
+
+// CreateAndBindSyslogDrain implements CfWorkflow. It registers sinkRoute
+// as a user-provided syslog drain service and binds it to this
+// workflow's app; the bind only takes effect once the app is restaged.
+func (w *cfWorkflow) CreateAndBindSyslogDrain(sinkRoute string) []cmdStartWaiter.CmdStartWaiter {
+	g := w.defaultCmdGenerator()
+
+	return []cmdStartWaiter.CmdStartWaiter{
+		g.CreateUserProvidedService(syslogDrainServiceName, fmt.Sprintf("syslog://%s", sinkRoute)),
+		g.BindService(w.appName, syslogDrainServiceName),
+	}
+}
+
+// RestageApp implements CfWorkflow. It picks up the syslog drain bind
+// CreateAndBindSyslogDrain made.
+func (w *cfWorkflow) RestageApp() []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{w.defaultCmdGenerator().Restage(w.appName)}
+}
+
+// EmitLog implements CfWorkflow. It has the app log a line containing
+// tag over `cf ssh`, so a syslog drain measurement can look for tag in
+// the bound sink app's logs afterward.
+func (w *cfWorkflow) EmitLog(g cfCmdGenerator.CfCmdGenerator, tag string) []cmdStartWaiter.CmdStartWaiter {
+	return []cmdStartWaiter.CmdStartWaiter{g.Ssh(w.appName, fmt.Sprintf("echo %s", tag))}
+}
+
+// AppUrl implements CfWorkflow.
+func (w *cfWorkflow) AppUrl() string {
+	return fmt.Sprintf("https://%s.%s", w.appName, w.cfc.AppDomain)
+}
+
+// defaultCmdGenerator lazily builds the CfCmdGenerator used for commands
+// this workflow issues on its own behalf rather than at a caller's
+// request, creating its CF_HOME the first time it's needed.
+func (w *cfWorkflow) defaultCmdGenerator() cfCmdGenerator.CfCmdGenerator {
+	if w.cfHomeDir == "" {
+		dir, err := ioutil.TempDir("", "uptimer-cf-workflow")
+		if err != nil {
+			dir = os.TempDir()
+		}
+		w.cfHomeDir = dir
+	}
+
+	return cfCmdGenerator.New(w.cfHomeDir)
+}
@@ -0,0 +1,64 @@
+package cfWorkflow
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/config"
+)
+
+func newTestWorkflow() CfWorkflow {
+	return New(
+		&config.Cf{API: "https://api.example.com", AppDomain: "apps.example.com"},
+		"org", "space", "quota", "app", "/tmp/app", "./app",
+	)
+}
+
+func TestSetupTargetsAuthsAndCreatesOrgSpaceQuota(t *testing.T) {
+	w := newTestWorkflow()
+	g := cfCmdGenerator.New("")
+
+	if got := len(w.Setup(g)); got != 7 {
+		t.Errorf("expected 7 commands, got %d", got)
+	}
+}
+
+func TestTearDownDeletesOrgAndQuota(t *testing.T) {
+	w := newTestWorkflow()
+	g := cfCmdGenerator.New("")
+
+	if got := len(w.TearDown(g)); got != 2 {
+		t.Errorf("expected 2 commands, got %d", got)
+	}
+}
+
+func TestAppUrlUsesAppNameAndDomain(t *testing.T) {
+	w := newTestWorkflow()
+
+	want := "https://app.apps.example.com"
+	if got := w.AppUrl(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDefaultCmdGeneratorReusesTheSameCfHomeDir(t *testing.T) {
+	w := newTestWorkflow().(*cfWorkflow)
+
+	first := w.defaultCmdGenerator()
+	second := w.defaultCmdGenerator()
+
+	if w.cfHomeDir == "" {
+		t.Fatal("expected cfHomeDir to be populated")
+	}
+	if first != second {
+		t.Error("expected defaultCmdGenerator to reuse the same CfCmdGenerator across calls")
+	}
+}
+
+func TestCreateAndBindSyslogDrainCreatesAndBindsTheService(t *testing.T) {
+	w := newTestWorkflow()
+
+	if got := len(w.CreateAndBindSyslogDrain("sink.apps.example.com")); got != 2 {
+		t.Errorf("expected 2 commands, got %d", got)
+	}
+}
@@ -0,0 +1,82 @@
+// Package cmdRunner runs a sequence of cmdStartWaiters, streaming each
+// one's stdout/stderr into a shared pair of writers so a measurement or
+// workflow step can inspect the combined output afterward.
+package cmdRunner
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// CmdRunner runs commands one after another, stopping at the first one
+// that fails.
+type CmdRunner interface {
+	RunInSequence(cmds ...cmdStartWaiter.CmdStartWaiter) error
+}
+
+type cmdRunner struct {
+	stdOut, stdErr io.Writer
+	copyFunc       func(dst io.Writer, src io.Reader) (int64, error)
+	timeout        time.Duration
+}
+
+// New builds a CmdRunner that streams each command's stdout/stderr into
+// stdOut/stdErr via copyFunc. It waits indefinitely for each command to
+// finish; use NewWithTimeout to bound that wait.
+func New(stdOut, stdErr io.Writer, copyFunc func(dst io.Writer, src io.Reader) (int64, error)) CmdRunner {
+	return &cmdRunner{stdOut: stdOut, stdErr: stdErr, copyFunc: copyFunc}
+}
+
+// NewWithTimeout builds a CmdRunner like New, except each command is
+// killed if it hasn't finished within timeout, so a hung `cf` invocation
+// can't block shutdown forever.
+func NewWithTimeout(stdOut, stdErr io.Writer, copyFunc func(dst io.Writer, src io.Reader) (int64, error), timeout time.Duration) CmdRunner {
+	return &cmdRunner{stdOut: stdOut, stdErr: stdErr, copyFunc: copyFunc, timeout: timeout}
+}
+
+// RunInSequence implements CmdRunner.
+func (c *cmdRunner) RunInSequence(cmds ...cmdStartWaiter.CmdStartWaiter) error {
+	for _, cmd := range cmds {
+		if err := c.run(cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cmdRunner) run(cmd cmdStartWaiter.CmdStartWaiter) error {
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	go c.copyFunc(c.stdOut, stdoutPipe)
+	go c.copyFunc(c.stdErr, stderrPipe)
+
+	if c.timeout == 0 {
+		return cmd.Wait()
+	}
+
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- cmd.Wait() }()
+
+	select {
+	case err := <-waitCh:
+		return err
+	case <-time.After(c.timeout):
+		cmd.Kill()
+		return fmt.Errorf("command timed out after %s", c.timeout)
+	}
+}
@@ -0,0 +1,99 @@
+package cmdRunner
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeCmd struct {
+	stdout, stderr string
+	startErr       error
+	waitDelay      time.Duration
+	waitErr        error
+	killed         bool
+}
+
+func (f *fakeCmd) StdoutPipe() (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(f.stdout)), nil
+}
+
+func (f *fakeCmd) StderrPipe() (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(f.stderr)), nil
+}
+
+func (f *fakeCmd) Start() error {
+	return f.startErr
+}
+
+func (f *fakeCmd) Wait() error {
+	time.Sleep(f.waitDelay)
+	return f.waitErr
+}
+
+func (f *fakeCmd) Kill() error {
+	f.killed = true
+	return nil
+}
+
+func TestRunInSequenceStopsAtFirstError(t *testing.T) {
+	errFailed := errors.New("boom")
+	outBuf, errBuf := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	runner := New(outBuf, errBuf, io.Copy)
+
+	second := &fakeCmd{}
+	err := runner.RunInSequence(&fakeCmd{startErr: errFailed}, second)
+	if err != errFailed {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+}
+
+func TestRunInSequenceStreamsOutput(t *testing.T) {
+	outBuf, errBuf := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	runner := New(outBuf, errBuf, io.Copy)
+
+	if err := runner.RunInSequence(&fakeCmd{stdout: "out", stderr: "err"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for outBuf.String() != "out" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := outBuf.String(); got != "out" {
+		t.Errorf("expected stdout %q, got %q", "out", got)
+	}
+	if got := errBuf.String(); got != "err" {
+		t.Errorf("expected stderr %q, got %q", "err", got)
+	}
+}
+
+func TestNewWithTimeoutKillsHungCommand(t *testing.T) {
+	outBuf, errBuf := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	runner := NewWithTimeout(outBuf, errBuf, io.Copy, 10*time.Millisecond)
+
+	cmd := &fakeCmd{waitDelay: time.Hour}
+	if err := runner.RunInSequence(cmd); err == nil {
+		t.Fatal("expected a timeout error, got nil")
+	}
+	if !cmd.killed {
+		t.Error("expected the hung command to be killed")
+	}
+}
+
+func TestNewWithTimeoutLeavesFastCommandsAlone(t *testing.T) {
+	outBuf, errBuf := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	runner := NewWithTimeout(outBuf, errBuf, io.Copy, time.Second)
+
+	cmd := &fakeCmd{}
+	if err := runner.RunInSequence(cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.killed {
+		t.Error("expected a fast command not to be killed")
+	}
+}
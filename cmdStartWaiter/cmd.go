@@ -0,0 +1,24 @@
+package cmdStartWaiter
+
+import "os/exec"
+
+// Wrap adapts an *exec.Cmd to CmdStartWaiter, adding the Kill method
+// *exec.Cmd doesn't have on its own.
+func Wrap(cmd *exec.Cmd) CmdStartWaiter {
+	return &execCmd{cmd}
+}
+
+type execCmd struct {
+	*exec.Cmd
+}
+
+// Kill terminates the underlying process. It's a no-op if Start hasn't
+// been called yet, so a cmdRunner can call it unconditionally on timeout
+// without checking whether the command ever started.
+func (c *execCmd) Kill() error {
+	if c.Process == nil {
+		return nil
+	}
+
+	return c.Process.Kill()
+}
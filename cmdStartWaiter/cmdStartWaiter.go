@@ -0,0 +1,20 @@
+// Package cmdStartWaiter defines the narrow interface cmdRunner needs to
+// drive a command, so measurements and workflows can be tested against
+// fakes instead of real `cf` invocations.
+package cmdStartWaiter
+
+import "io"
+
+// CmdStartWaiter is satisfied by the adapter Wrap builds around an
+// *exec.Cmd, which adds the Kill method. Splitting it out from
+// os/exec lets cmdRunner stream a command's output and wait on it without
+// knowing whether it's talking to a real process or a fake. Kill lets a
+// timeout-bearing cmdRunner reclaim a command that's overrun its budget
+// instead of leaking it and its copy goroutines.
+type CmdStartWaiter interface {
+	StdoutPipe() (io.ReadCloser, error)
+	StderrPipe() (io.ReadCloser, error)
+	Start() error
+	Wait() error
+	Kill() error
+}
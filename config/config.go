@@ -9,6 +9,22 @@ type Config struct {
 	While           []*Command      `json:"while"`
 	CF              *Cf             `json:"cf"`
 	AllowedFailures AllowedFailures `json:"allowed_failures"`
+	PrometheusPort  int             `json:"prometheus_port"`
+	Artifacts       *Artifacts      `json:"artifacts"`
+	ShutdownTimeout string          `json:"shutdown_timeout"`
+}
+
+// Artifacts configures where run artifacts are uploaded for post-mortem
+// analysis. A run's artifacts are its buffered command output, written at
+// teardown as stdout.log and stderr.log; measurement results are not
+// written as artifacts. Credentials are taken from the environment rather
+// than the config file.
+type Artifacts struct {
+	Provider      string `json:"provider"`
+	Bucket        string `json:"bucket"`
+	Region        string `json:"region"`
+	Prefix        string `json:"prefix"`
+	SweepInterval string `json:"sweep_interval"`
 }
 
 type Command struct {
@@ -31,6 +47,7 @@ type AllowedFailures struct {
 	HttpAvailability int `json:"http_availability"`
 	RecentLogs       int `json:"recent_logs"`
 	StreamingLogs    int `json:"streaming_logs"`
+	SyslogDrain      int `json:"syslog_drain"`
 }
 
 func Load(filename string) (*Config, error) {
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Override applies any CLI flags or environment variables set on c onto
+// cfg, taking precedence over whatever was loaded from the config file.
+// Flags carry their own `--foo`/`UPTIMER_FOO` env var pairing, so by the
+// time a flag reports as set here, the library has already resolved
+// flag-vs-env precedence for us; Override only decides file-vs-CLI.
+func Override(cfg *Config, c *cli.Context) {
+	if cfg.CF == nil {
+		cfg.CF = &Cf{}
+	}
+
+	cfg.CF.API = overrideString(cfg.CF.API, c, "cf-api")
+	cfg.CF.AppDomain = overrideString(cfg.CF.AppDomain, c, "cf-app-domain")
+	cfg.CF.AdminUser = overrideString(cfg.CF.AdminUser, c, "cf-admin-user")
+	cfg.CF.AdminPassword = overrideString(cfg.CF.AdminPassword, c, "cf-admin-password")
+	cfg.CF.TCPDomain = overrideString(cfg.CF.TCPDomain, c, "cf-tcp-domain")
+	cfg.CF.AvailablePort = overrideInt(cfg.CF.AvailablePort, c, "cf-available-port")
+
+	cfg.AllowedFailures.AppPushability = overrideInt(cfg.AllowedFailures.AppPushability, c, "allowed-failures-app-pushability")
+	cfg.AllowedFailures.HttpAvailability = overrideInt(cfg.AllowedFailures.HttpAvailability, c, "allowed-failures-http-availability")
+	cfg.AllowedFailures.RecentLogs = overrideInt(cfg.AllowedFailures.RecentLogs, c, "allowed-failures-recent-logs")
+	cfg.AllowedFailures.StreamingLogs = overrideInt(cfg.AllowedFailures.StreamingLogs, c, "allowed-failures-streaming-logs")
+	cfg.AllowedFailures.SyslogDrain = overrideInt(cfg.AllowedFailures.SyslogDrain, c, "allowed-failures-syslog-drain")
+}
+
+func overrideString(current string, c *cli.Context, flagName string) string {
+	if c.IsSet(flagName) {
+		return c.String(flagName)
+	}
+
+	return current
+}
+
+func overrideInt(current int, c *cli.Context, flagName string) int {
+	if c.IsSet(flagName) {
+		return c.Int(flagName)
+	}
+
+	return current
+}
+
+// Flags returns the set of CLI flags that can override config.Cf and
+// config.AllowedFailures fields, shared by every subcommand that loads a
+// config file.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "cf-api", EnvVars: []string{"UPTIMER_CF_API"}, Usage: "CF API endpoint"},
+		&cli.StringFlag{Name: "cf-app-domain", EnvVars: []string{"UPTIMER_CF_APP_DOMAIN"}, Usage: "CF app domain"},
+		&cli.StringFlag{Name: "cf-admin-user", EnvVars: []string{"UPTIMER_CF_ADMIN_USER"}, Usage: "CF admin username"},
+		&cli.StringFlag{Name: "cf-admin-password", EnvVars: []string{"UPTIMER_CF_ADMIN_PASSWORD"}, Usage: "CF admin password"},
+		&cli.StringFlag{Name: "cf-tcp-domain", EnvVars: []string{"UPTIMER_CF_TCP_DOMAIN"}, Usage: "CF TCP domain"},
+		&cli.IntFlag{Name: "cf-available-port", EnvVars: []string{"UPTIMER_CF_AVAILABLE_PORT"}, Usage: "Available TCP port for CF"},
+		&cli.IntFlag{Name: "allowed-failures-app-pushability", EnvVars: []string{"UPTIMER_ALLOWED_FAILURES_APP_PUSHABILITY"}, Usage: "Allowed failures for the app pushability measurement"},
+		&cli.IntFlag{Name: "allowed-failures-http-availability", EnvVars: []string{"UPTIMER_ALLOWED_FAILURES_HTTP_AVAILABILITY"}, Usage: "Allowed failures for the HTTP availability measurement"},
+		&cli.IntFlag{Name: "allowed-failures-recent-logs", EnvVars: []string{"UPTIMER_ALLOWED_FAILURES_RECENT_LOGS"}, Usage: "Allowed failures for the recent logs measurement"},
+		&cli.IntFlag{Name: "allowed-failures-streaming-logs", EnvVars: []string{"UPTIMER_ALLOWED_FAILURES_STREAMING_LOGS"}, Usage: "Allowed failures for the streaming logs measurement"},
+		&cli.IntFlag{Name: "allowed-failures-syslog-drain", EnvVars: []string{"UPTIMER_ALLOWED_FAILURES_SYSLOG_DRAIN"}, Usage: "Allowed failures for the syslog drain measurement"},
+	}
+}
+
+// Validate schema-checks cfg without touching CF, for the validate-config
+// subcommand.
+func Validate(cfg *Config) error {
+	if cfg.CF == nil {
+		return errRequired("cf")
+	}
+	if cfg.CF.API == "" {
+		return errRequired("cf.api")
+	}
+	if cfg.CF.AppDomain == "" {
+		return errRequired("cf.app_domain")
+	}
+	if cfg.CF.AdminUser == "" {
+		return errRequired("cf.admin_user")
+	}
+	if cfg.CF.AdminPassword == "" {
+		return errRequired("cf.admin_password")
+	}
+
+	return nil
+}
+
+func errRequired(field string) error {
+	return &missingFieldError{field}
+}
+
+type missingFieldError struct {
+	field string
+}
+
+func (e *missingFieldError) Error() string {
+	return "config is missing required field: " + e.field
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func contextWithFlags(set map[string]string) *cli.Context {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range Flags() {
+		f.Apply(fs)
+	}
+	for name, value := range set {
+		fs.Set(name, value)
+	}
+
+	return cli.NewContext(cli.NewApp(), fs, nil)
+}
+
+func TestOverrideLeavesFileValuesWhenNoFlagsSet(t *testing.T) {
+	cfg := &Config{CF: &Cf{API: "https://file-api.example.com", AvailablePort: 1111}}
+
+	Override(cfg, contextWithFlags(nil))
+
+	if cfg.CF.API != "https://file-api.example.com" {
+		t.Errorf("expected file value to survive, got %q", cfg.CF.API)
+	}
+	if cfg.CF.AvailablePort != 1111 {
+		t.Errorf("expected file value to survive, got %d", cfg.CF.AvailablePort)
+	}
+}
+
+func TestOverrideAppliesSetFlagsOverFileValues(t *testing.T) {
+	cfg := &Config{CF: &Cf{API: "https://file-api.example.com", AvailablePort: 1111}}
+
+	Override(cfg, contextWithFlags(map[string]string{
+		"cf-api":            "https://flag-api.example.com",
+		"cf-available-port": "2222",
+	}))
+
+	if cfg.CF.API != "https://flag-api.example.com" {
+		t.Errorf("expected flag value to win, got %q", cfg.CF.API)
+	}
+	if cfg.CF.AvailablePort != 2222 {
+		t.Errorf("expected flag value to win, got %d", cfg.CF.AvailablePort)
+	}
+}
+
+func TestOverrideCreatesCfWhenNil(t *testing.T) {
+	cfg := &Config{}
+
+	Override(cfg, contextWithFlags(map[string]string{"cf-api": "https://flag-api.example.com"}))
+
+	if cfg.CF == nil {
+		t.Fatal("expected CF to be initialized")
+	}
+	if cfg.CF.API != "https://flag-api.example.com" {
+		t.Errorf("expected flag value, got %q", cfg.CF.API)
+	}
+}
+
+func TestOverrideAppliesAllowedFailuresFlags(t *testing.T) {
+	cfg := &Config{CF: &Cf{}, AllowedFailures: AllowedFailures{SyslogDrain: 3}}
+
+	Override(cfg, contextWithFlags(map[string]string{"allowed-failures-syslog-drain": "7"}))
+
+	if cfg.AllowedFailures.SyslogDrain != 7 {
+		t.Errorf("expected flag value to win, got %d", cfg.AllowedFailures.SyslogDrain)
+	}
+}
+
+func TestValidateRequiresCfFields(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  *Config
+	}{
+		{"nil cf", &Config{}},
+		{"missing api", &Config{CF: &Cf{AppDomain: "x", AdminUser: "x", AdminPassword: "x"}}},
+		{"missing app domain", &Config{CF: &Cf{API: "x", AdminUser: "x", AdminPassword: "x"}}},
+		{"missing admin user", &Config{CF: &Cf{API: "x", AppDomain: "x", AdminPassword: "x"}}},
+		{"missing admin password", &Config{CF: &Cf{API: "x", AppDomain: "x", AdminUser: "x"}}},
+	}
+
+	for _, c := range cases {
+		if err := Validate(c.cfg); err == nil {
+			t.Errorf("%s: expected an error, got nil", c.name)
+		}
+	}
+}
+
+func TestValidatePassesWithAllRequiredFields(t *testing.T) {
+	cfg := &Config{CF: &Cf{API: "x", AppDomain: "x", AdminUser: "x", AdminPassword: "x"}}
+
+	if err := Validate(cfg); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package logger wraps zerolog behind a small interface so the rest of
+// uptimer can emit structured, leveled log lines without depending on
+// zerolog directly.
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// Logger is the structured logger threaded through the orchestrator,
+// measurement, cmdRunner, and cfWorkflow packages. Fields attached via
+// WithFields are included on every subsequent call made through the
+// returned Logger.
+type Logger interface {
+	Debug(msg string)
+	Info(msg string)
+	Error(msg string, err error)
+	WithFields(fields map[string]interface{}) Logger
+}
+
+type zeroLogger struct {
+	log zerolog.Logger
+}
+
+// Format selects how log lines are rendered.
+type Format string
+
+const (
+	// FormatJSON emits one JSON object per line, suitable for ELK/Loki.
+	FormatJSON Format = "json"
+	// FormatText emits a human-readable console line, for local runs.
+	FormatText Format = "text"
+)
+
+// New builds a Logger that writes to w, rendered according to format and
+// filtered to level (one of zerolog's level names: "debug", "info",
+// "warn", "error").
+func New(w io.Writer, format Format, level string) Logger {
+	parsedLevel, err := zerolog.ParseLevel(level)
+	if err != nil {
+		parsedLevel = zerolog.InfoLevel
+	}
+
+	var output io.Writer = w
+	if format == FormatText {
+		output = zerolog.ConsoleWriter{Out: w, TimeFormat: "15:04:05"}
+	}
+
+	zl := zerolog.New(output).Level(parsedLevel).With().Timestamp().Logger()
+
+	return &zeroLogger{log: zl}
+}
+
+// NewDefault builds a Logger writing JSON to stdout at info level, used
+// wherever a caller doesn't need to configure format or level.
+func NewDefault() Logger {
+	return New(os.Stdout, FormatJSON, "info")
+}
+
+func (z *zeroLogger) Debug(msg string) {
+	z.log.Debug().Msg(msg)
+}
+
+func (z *zeroLogger) Info(msg string) {
+	z.log.Info().Msg(msg)
+}
+
+func (z *zeroLogger) Error(msg string, err error) {
+	z.log.Error().Err(err).Msg(msg)
+}
+
+func (z *zeroLogger) WithFields(fields map[string]interface{}) Logger {
+	ctx := z.log.With()
+	for k, v := range fields {
+		ctx = ctx.Interface(k, v)
+	}
+
+	return &zeroLogger{log: ctx.Logger()}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewFiltersBelowConfiguredLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(buf, FormatJSON, "info")
+
+	log.Debug("should be filtered")
+	log.Info("should appear")
+
+	out := buf.String()
+	if strings.Contains(out, "should be filtered") {
+		t.Error("expected debug line to be filtered out at info level")
+	}
+	if !strings.Contains(out, "should appear") {
+		t.Error("expected info line to appear")
+	}
+}
+
+func TestErrorIncludesTheErrorMessage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(buf, FormatJSON, "debug")
+
+	log.Error("it broke", errors.New("disk full"))
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected valid JSON, got error %v for %q", err, buf.String())
+	}
+	if line["error"] != "disk full" {
+		t.Errorf("expected error field %q, got %v", "disk full", line["error"])
+	}
+}
+
+func TestWithFieldsAttachesFieldsToSubsequentCalls(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := New(buf, FormatJSON, "info").WithFields(map[string]interface{}{"org": "my-org"})
+
+	log.Info("setting up")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected valid JSON, got error %v for %q", err, buf.String())
+	}
+	if line["org"] != "my-org" {
+		t.Errorf("expected org field %q, got %v", "my-org", line["org"])
+	}
+}
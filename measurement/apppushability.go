@@ -0,0 +1,52 @@
+package measurement
+
+import (
+	"bytes"
+
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// AppPushability measures whether a fresh app can still be pushed and
+// deleted, catching Cloud Controller or stager problems that an
+// already-running app wouldn't reveal.
+type AppPushability struct {
+	pushAndDeleteCmd func() []cmdStartWaiter.CmdStartWaiter
+	runner           cmdRunner.CmdRunner
+	outBuf, errBuf   *bytes.Buffer
+}
+
+// NewAppPushability builds an AppPushability measurement.
+// pushAndDeleteCmd should produce the commands that push and then delete
+// a throwaway app.
+func NewAppPushability(
+	pushAndDeleteCmd func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+) *AppPushability {
+	return &AppPushability{
+		pushAndDeleteCmd: pushAndDeleteCmd,
+		runner:           runner,
+		outBuf:           outBuf,
+		errBuf:           errBuf,
+	}
+}
+
+// Name implements measurer.
+func (a *AppPushability) Name() string {
+	return "AppPushability"
+}
+
+// PerformMeasurement implements measurer.
+func (a *AppPushability) PerformMeasurement() (bool, error) {
+	err := a.runner.RunInSequence(a.pushAndDeleteCmd()...)
+	a.outBuf.Reset()
+	a.errBuf.Reset()
+
+	return err == nil, err
+}
+
+// Failure describes what a failed AppPushability measurement means.
+func (a *AppPushability) Failure() string {
+	return "App pushability: push and delete of a throwaway app failed"
+}
@@ -0,0 +1,53 @@
+package measurement
+
+import (
+	"net/http"
+	"time"
+)
+
+// HTTPAvailability measures whether the orc app still responds to HTTP
+// requests with a 200, and records how long each request took.
+type HTTPAvailability struct {
+	url          string
+	client       *http.Client
+	promExporter *PrometheusExporter
+}
+
+// NewHTTPAvailability builds an HTTPAvailability measurement against
+// url. promExporter may be nil, in which case request latency is
+// measured but not recorded anywhere.
+func NewHTTPAvailability(url string, client *http.Client, promExporter *PrometheusExporter) *HTTPAvailability {
+	return &HTTPAvailability{
+		url:          url,
+		client:       client,
+		promExporter: promExporter,
+	}
+}
+
+// Name implements measurer.
+func (h *HTTPAvailability) Name() string {
+	return "HTTPAvailability"
+}
+
+// PerformMeasurement implements measurer.
+func (h *HTTPAvailability) PerformMeasurement() (bool, error) {
+	start := time.Now()
+	resp, err := h.client.Get(h.url)
+	elapsed := time.Since(start)
+
+	if h.promExporter != nil {
+		h.promExporter.ObserveHTTPLatency(h.Name(), elapsed.Seconds())
+	}
+
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// Failure describes what a failed HTTPAvailability measurement means.
+func (h *HTTPAvailability) Failure() string {
+	return "HTTP availability: request to the orc app did not return 200"
+}
@@ -0,0 +1,71 @@
+// Package measurement implements the individual checks uptimer runs
+// against a Cloud Foundry deployment (app pushability, HTTP availability,
+// recent/streaming logs, syslog drain), plus the Periodic scheduler and Prometheus
+// exporter that wrap them for a live run.
+package measurement
+
+import "context"
+
+// Measurement is what the orchestrator manages: something with a
+// lifecycle it can start alongside a run and stop once the run is done.
+// Periodic is the only implementation; it's what createMeasurements
+// hands back to orchestrator.New. Start takes a context so a canceled
+// run falls straight through to Stop instead of waiting out the current
+// period.
+type Measurement interface {
+	Start(ctx context.Context)
+	Stop()
+}
+
+// measurer is a single check Periodic runs on a schedule. It's
+// unexported because callers only ever construct one via
+// NewRecentLogs/NewStreamingLogs/NewAppPushability/NewHTTPAvailability/
+// NewSyslogDrain and hand it straight to NewPeriodic.
+type measurer interface {
+	Name() string
+	PerformMeasurement() (bool, error)
+}
+
+// ResultSet accumulates pass/fail outcomes for a single measurer across
+// a run, so Periodic can derive a failure budget and an availability
+// ratio from it.
+type ResultSet struct {
+	successes int
+	failures  int
+}
+
+// NewResultSet builds an empty ResultSet.
+func NewResultSet() *ResultSet {
+	return &ResultSet{}
+}
+
+// Record adds one outcome to the set.
+func (r *ResultSet) Record(successful bool) {
+	if successful {
+		r.successes++
+	} else {
+		r.failures++
+	}
+}
+
+// TotalFailed returns the number of recorded failures.
+func (r *ResultSet) TotalFailed() int {
+	return r.failures
+}
+
+// TotalSuccessful returns the number of recorded successes.
+func (r *ResultSet) TotalSuccessful() int {
+	return r.successes
+}
+
+// Availability returns the fraction of recorded outcomes that
+// succeeded. An empty ResultSet reports full availability, since no
+// failure has been observed yet.
+func (r *ResultSet) Availability() float64 {
+	total := r.successes + r.failures
+	if total == 0 {
+		return 1
+	}
+
+	return float64(r.successes) / float64(total)
+}
@@ -0,0 +1,29 @@
+package measurement
+
+import "testing"
+
+func TestResultSetAvailabilityIsFullWhenEmpty(t *testing.T) {
+	r := NewResultSet()
+
+	if got := r.Availability(); got != 1 {
+		t.Errorf("expected 1, got %v", got)
+	}
+}
+
+func TestResultSetTracksSuccessesAndFailures(t *testing.T) {
+	r := NewResultSet()
+
+	r.Record(true)
+	r.Record(true)
+	r.Record(false)
+
+	if got := r.TotalSuccessful(); got != 2 {
+		t.Errorf("expected 2 successes, got %d", got)
+	}
+	if got := r.TotalFailed(); got != 1 {
+		t.Errorf("expected 1 failure, got %d", got)
+	}
+	if got := r.Availability(); got != float64(2)/float64(3) {
+		t.Errorf("expected 2/3, got %v", got)
+	}
+}
@@ -0,0 +1,117 @@
+package measurement
+
+import (
+	"context"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/logger"
+)
+
+// Periodic runs a measurer on a fixed schedule, recording each result
+// into a ResultSet and, when provided, a PrometheusExporter, until Stop
+// is called.
+type Periodic struct {
+	log             logger.Logger
+	clock           clock.Clock
+	period          time.Duration
+	measurement     measurer
+	resultSet       *ResultSet
+	allowedFailures int
+	retryFunc       func(stdOut, stdErr string) bool
+	promExporter    *PrometheusExporter
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewPeriodic builds a Periodic that runs m every period. allowedFailures
+// is the failure budget from which the allowed-failures-remaining count is
+// computed, logged on each failure and exported; exceeding it does not
+// stop the run or change its exit code.
+// retryFunc is unused by Periodic itself today; it's threaded through so
+// callers can make auth-expiry-aware decisions about a failure in a
+// future change without another signature churn. promExporter may be
+// nil, in which case results are only recorded into resultSet.
+func NewPeriodic(
+	log logger.Logger,
+	clk clock.Clock,
+	period time.Duration,
+	m measurer,
+	resultSet *ResultSet,
+	allowedFailures int,
+	retryFunc func(stdOut, stdErr string) bool,
+	promExporter *PrometheusExporter,
+) *Periodic {
+	return &Periodic{
+		log:             log,
+		clock:           clk,
+		period:          period,
+		measurement:     m,
+		resultSet:       resultSet,
+		allowedFailures: allowedFailures,
+		retryFunc:       retryFunc,
+		promExporter:    promExporter,
+		stopCh:          make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+// Start implements Measurement. It runs the wrapped measurer on a timer
+// until Stop is called or ctx is done.
+func (p *Periodic) Start(ctx context.Context) {
+	go func() {
+		defer close(p.doneCh)
+
+		ticker := p.clock.Ticker(p.period)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.runOnce()
+			case <-ctx.Done():
+				return
+			case <-p.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop implements Measurement. It ends the schedule and blocks until the
+// in-flight measurement, if any, has finished.
+func (p *Periodic) Stop() {
+	close(p.stopCh)
+	<-p.doneCh
+}
+
+func (p *Periodic) runOnce() {
+	name := p.measurement.Name()
+
+	successful, err := p.measurement.PerformMeasurement()
+	if err != nil {
+		successful = false
+	}
+
+	p.resultSet.Record(successful)
+	remaining := p.allowedFailures - p.resultSet.TotalFailed()
+
+	if p.promExporter != nil {
+		p.promExporter.Observe(name, successful, remaining)
+		p.promExporter.SetAvailability(name, p.resultSet.Availability())
+	}
+
+	if !successful {
+		fields := map[string]interface{}{
+			"measurement":               name,
+			"allowed_failures_remaining": remaining,
+		}
+		if err != nil {
+			p.log.WithFields(fields).Error("Measurement failed", err)
+		} else {
+			p.log.WithFields(fields).Info("Measurement failed")
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package measurement
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/cloudfoundry/uptimer/logger"
+)
+
+type nullLogger struct{}
+
+func (nullLogger) Debug(msg string)           {}
+func (nullLogger) Info(msg string)            {}
+func (nullLogger) Error(msg string, err error) {}
+func (l nullLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	return l
+}
+
+type countingMeasurer struct {
+	mu    sync.Mutex
+	calls int
+	ok    bool
+}
+
+func (c *countingMeasurer) Name() string { return "counting" }
+
+func (c *countingMeasurer) PerformMeasurement() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.ok, nil
+}
+
+func (c *countingMeasurer) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestPeriodicRunsOnEveryTick(t *testing.T) {
+	mockClock := clock.NewMock()
+	m := &countingMeasurer{ok: true}
+	p := NewPeriodic(nullLogger{}, mockClock, time.Second, m, NewResultSet(), 0, nil, nil)
+
+	p.Start(context.Background())
+	for i := 0; i < 3; i++ {
+		mockClock.Add(time.Second)
+	}
+	p.Stop()
+
+	if got := m.count(); got != 3 {
+		t.Errorf("expected 3 measurements, got %d", got)
+	}
+}
+
+func TestPeriodicStopBlocksUntilInFlightMeasurementFinishes(t *testing.T) {
+	mockClock := clock.NewMock()
+	m := &countingMeasurer{ok: true}
+	p := NewPeriodic(nullLogger{}, mockClock, time.Second, m, NewResultSet(), 0, nil, nil)
+
+	p.Start(context.Background())
+	mockClock.Add(time.Second)
+	p.Stop()
+
+	if got := m.count(); got != 1 {
+		t.Errorf("expected exactly 1 measurement by the time Stop returned, got %d", got)
+	}
+}
+
+func TestPeriodicStartReturnsWhenContextCanceled(t *testing.T) {
+	mockClock := clock.NewMock()
+	m := &countingMeasurer{ok: true}
+	p := NewPeriodic(nullLogger{}, mockClock, time.Second, m, NewResultSet(), 0, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		<-p.doneCh
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected canceling ctx to end the Periodic loop")
+	}
+}
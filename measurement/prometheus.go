@@ -0,0 +1,125 @@
+package measurement
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PrometheusExporter wraps a set of Periodic measurements with Prometheus
+// collectors so that a run can be scraped for live dashboards and alerting,
+// rather than only summarized at teardown.
+type PrometheusExporter struct {
+	server *http.Server
+
+	successTotal  *prometheus.CounterVec
+	failureTotal  *prometheus.CounterVec
+	availability  *prometheus.GaugeVec
+	httpLatency   *prometheus.HistogramVec
+	failureBudget *prometheus.GaugeVec
+}
+
+// NewPrometheusExporter creates an exporter with its collectors registered
+// against a dedicated registry, so a scrape only ever exposes uptimer's own
+// metrics.
+func NewPrometheusExporter() *PrometheusExporter {
+	successTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "uptimer_measurement_success_total",
+			Help: "Total number of successful measurement runs, by measurement name.",
+		},
+		[]string{"measurement"},
+	)
+	failureTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "uptimer_measurement_failure_total",
+			Help: "Total number of failed measurement runs, by measurement name.",
+		},
+		[]string{"measurement"},
+	)
+	availability := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "uptimer_measurement_availability_ratio",
+			Help: "Current availability (successes / total) for a measurement.",
+		},
+		[]string{"measurement"},
+	)
+	httpLatency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "uptimer_http_availability_request_duration_seconds",
+			Help:    "Latency of HTTPAvailability requests against the pushed app.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"measurement"},
+	)
+	failureBudget := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "uptimer_measurement_allowed_failures_remaining",
+			Help: "Allowed failures remaining in a measurement's failure budget; negative once the budget is exceeded.",
+		},
+		[]string{"measurement"},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(successTotal, failureTotal, availability, httpLatency, failureBudget)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	return &PrometheusExporter{
+		successTotal:  successTotal,
+		failureTotal:  failureTotal,
+		availability:  availability,
+		httpLatency:   httpLatency,
+		failureBudget: failureBudget,
+		server:        &http.Server{Handler: mux},
+	}
+}
+
+// Observe records the outcome of a single measurement result into the
+// exporter's collectors. It should be called each time a Periodic records a
+// result into its ResultSet.
+func (p *PrometheusExporter) Observe(name string, successful bool, allowedFailuresRemaining int) {
+	if successful {
+		p.successTotal.WithLabelValues(name).Inc()
+	} else {
+		p.failureTotal.WithLabelValues(name).Inc()
+	}
+	p.failureBudget.WithLabelValues(name).Set(float64(allowedFailuresRemaining))
+}
+
+// ObserveHTTPLatency records the duration of an HTTPAvailability request.
+func (p *PrometheusExporter) ObserveHTTPLatency(name string, seconds float64) {
+	p.httpLatency.WithLabelValues(name).Observe(seconds)
+}
+
+// SetAvailability records the current availability ratio for a measurement.
+func (p *PrometheusExporter) SetAvailability(name string, ratio float64) {
+	p.availability.WithLabelValues(name).Set(ratio)
+}
+
+// Start begins serving /metrics on the given port. It binds the
+// listener synchronously, so a bind failure (e.g. the port is already in
+// use) is returned directly to the caller instead of being lost in a
+// background goroutine.
+func (p *PrometheusExporter) Start(port int) error {
+	p.server.Addr = fmt.Sprintf(":%d", port)
+
+	listener, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		return fmt.Errorf("binding prometheus listener on %s: %w", p.server.Addr, err)
+	}
+
+	go p.server.Serve(listener)
+
+	return nil
+}
+
+// Stop shuts the metrics server down cleanly.
+func (p *PrometheusExporter) Stop() error {
+	return p.server.Shutdown(context.Background())
+}
@@ -0,0 +1,62 @@
+package measurement
+
+import (
+	"bytes"
+
+	"github.com/cloudfoundry/uptimer/appLogValidator"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// RecentLogs measures whether `cf logs --recent` against the orc app
+// still returns something, catching a loggregator outage that a pushed,
+// running app wouldn't otherwise surface.
+type RecentLogs struct {
+	recentLogsCmd  func() []cmdStartWaiter.CmdStartWaiter
+	runner         cmdRunner.CmdRunner
+	outBuf, errBuf *bytes.Buffer
+	validator      appLogValidator.AppLogValidator
+}
+
+// NewRecentLogs builds a RecentLogs measurement. recentLogsCmd should
+// produce the commands that fetch the orc app's recent logs.
+func NewRecentLogs(
+	recentLogsCmd func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+	validator appLogValidator.AppLogValidator,
+) *RecentLogs {
+	return &RecentLogs{
+		recentLogsCmd: recentLogsCmd,
+		runner:        runner,
+		outBuf:        outBuf,
+		errBuf:        errBuf,
+		validator:     validator,
+	}
+}
+
+// Name implements measurer.
+func (r *RecentLogs) Name() string {
+	return "RecentLogs"
+}
+
+// PerformMeasurement implements measurer.
+func (r *RecentLogs) PerformMeasurement() (bool, error) {
+	if err := r.runner.RunInSequence(r.recentLogsCmd()...); err != nil {
+		r.outBuf.Reset()
+		r.errBuf.Reset()
+
+		return false, err
+	}
+
+	found := r.validator.Validate(r.outBuf.String(), "")
+	r.outBuf.Reset()
+	r.errBuf.Reset()
+
+	return found, nil
+}
+
+// Failure describes what a failed RecentLogs measurement means.
+func (r *RecentLogs) Failure() string {
+	return "Recent logs: `cf logs --recent` returned no output"
+}
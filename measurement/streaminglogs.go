@@ -0,0 +1,66 @@
+package measurement
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cloudfoundry/uptimer/appLogValidator"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// StreamingLogs measures whether `cf logs` (the streaming tail, as
+// opposed to RecentLogs' `--recent` snapshot) still delivers output for
+// the orc app.
+type StreamingLogs struct {
+	streamCmd      func() (context.Context, context.CancelFunc, []cmdStartWaiter.CmdStartWaiter)
+	runner         cmdRunner.CmdRunner
+	outBuf, errBuf *bytes.Buffer
+	validator      appLogValidator.AppLogValidator
+}
+
+// NewStreamingLogs builds a StreamingLogs measurement. streamCmd should
+// return a context bounding how long the tail runs for, that context's
+// cancel func, and the commands that start the tail.
+func NewStreamingLogs(
+	streamCmd func() (context.Context, context.CancelFunc, []cmdStartWaiter.CmdStartWaiter),
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+	validator appLogValidator.AppLogValidator,
+) *StreamingLogs {
+	return &StreamingLogs{
+		streamCmd: streamCmd,
+		runner:    runner,
+		outBuf:    outBuf,
+		errBuf:    errBuf,
+		validator: validator,
+	}
+}
+
+// Name implements measurer.
+func (s *StreamingLogs) Name() string {
+	return "StreamingLogs"
+}
+
+// PerformMeasurement implements measurer. The tail is expected to end by
+// its own context timing out and killing the command, so a non-nil
+// RunInSequence error is not itself treated as failure; only the
+// accumulated output is.
+func (s *StreamingLogs) PerformMeasurement() (bool, error) {
+	ctx, cancel, cmds := s.streamCmd()
+	defer cancel()
+
+	_ = s.runner.RunInSequence(cmds...)
+	_ = ctx
+
+	found := s.validator.Validate(s.outBuf.String(), "")
+	s.outBuf.Reset()
+	s.errBuf.Reset()
+
+	return found, nil
+}
+
+// Failure describes what a failed StreamingLogs measurement means.
+func (s *StreamingLogs) Failure() string {
+	return "Streaming logs: `cf logs` produced no output before its tail ended"
+}
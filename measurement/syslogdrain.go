@@ -0,0 +1,77 @@
+package measurement
+
+import (
+	"bytes"
+	"fmt"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/cloudfoundry/uptimer/appLogValidator"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+)
+
+// SyslogDrain measures whether a loggregator syslog drain is still
+// delivering logs to a bound sink app. Each run tags a log line emitted
+// from the orc app with a fresh UUID, then greps `cf logs --recent`
+// against the sink app for that same UUID, so a match can only mean the
+// drain carried this exact line through.
+type SyslogDrain struct {
+	emitTaggedLog  func(tag string) []cmdStartWaiter.CmdStartWaiter
+	sinkRecentLogs func() []cmdStartWaiter.CmdStartWaiter
+	runner         cmdRunner.CmdRunner
+	outBuf, errBuf *bytes.Buffer
+	validator      appLogValidator.AppLogValidator
+}
+
+// NewSyslogDrain builds a SyslogDrain measurement. emitTaggedLog should
+// produce the commands that make the orc app log a line containing tag;
+// sinkRecentLogs should produce the commands that fetch the sink app's
+// recent logs.
+func NewSyslogDrain(
+	emitTaggedLog func(tag string) []cmdStartWaiter.CmdStartWaiter,
+	sinkRecentLogs func() []cmdStartWaiter.CmdStartWaiter,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+	validator appLogValidator.AppLogValidator,
+) *SyslogDrain {
+	return &SyslogDrain{
+		emitTaggedLog:  emitTaggedLog,
+		sinkRecentLogs: sinkRecentLogs,
+		runner:         runner,
+		outBuf:         outBuf,
+		errBuf:         errBuf,
+		validator:      validator,
+	}
+}
+
+// Name implements measurer.
+func (s *SyslogDrain) Name() string {
+	return "SyslogDrain"
+}
+
+// PerformMeasurement implements measurer. It emits a freshly tagged log
+// line from the orc app, then checks whether that tag shows up in the
+// sink app's recent logs.
+func (s *SyslogDrain) PerformMeasurement() (bool, error) {
+	tag := fmt.Sprintf("uptimer-syslog-drain-%s", uuid.NewV4().String())
+
+	if err := s.runner.RunInSequence(s.emitTaggedLog(tag)...); err != nil {
+		return false, fmt.Errorf("emitting tagged log line: %w", err)
+	}
+
+	if err := s.runner.RunInSequence(s.sinkRecentLogs()...); err != nil {
+		return false, fmt.Errorf("fetching sink app recent logs: %w", err)
+	}
+
+	found := s.validator.Validate(s.outBuf.String(), tag)
+	s.outBuf.Reset()
+	s.errBuf.Reset()
+
+	return found, nil
+}
+
+// Failure describes what a failed SyslogDrain measurement means.
+func (s *SyslogDrain) Failure() string {
+	return "Syslog drain: tagged log line did not appear in sink app's recent logs"
+}
@@ -0,0 +1,105 @@
+// Package orchestrator drives a single measurement run: standing up the
+// orc workflow's app, running the configured `while` commands alongside
+// the scheduled measurements, and tearing the workflow back down.
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cfWorkflow"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/config"
+	"github.com/cloudfoundry/uptimer/logger"
+	"github.com/cloudfoundry/uptimer/measurement"
+)
+
+// Orchestrator sets up the orc workflow, runs the `while` commands while
+// measurements are collected in the background, and tears the workflow
+// back down afterward.
+type Orchestrator interface {
+	Setup(runner cmdRunner.CmdRunner, cmdGenerator cfCmdGenerator.CfCmdGenerator) error
+	Run(ctx context.Context, performMeasurements bool) (int, error)
+	TearDown(runner cmdRunner.CmdRunner, cmdGenerator cfCmdGenerator.CfCmdGenerator) error
+}
+
+type orchestrator struct {
+	whileCommands []*config.Command
+	log           logger.Logger
+	workflow      cfWorkflow.CfWorkflow
+	measurements  []measurement.Measurement
+}
+
+// New builds an Orchestrator around workflow, running whileCommands to
+// completion while measurements are started and stopped around it.
+// Setup/TearDown take their own runner so callers can reuse the buffered
+// runner they already log failures from.
+func New(
+	whileCommands []*config.Command,
+	log logger.Logger,
+	workflow cfWorkflow.CfWorkflow,
+	measurements []measurement.Measurement,
+) Orchestrator {
+	return &orchestrator{
+		whileCommands: whileCommands,
+		log:           log,
+		workflow:      workflow,
+		measurements:  measurements,
+	}
+}
+
+// Setup implements Orchestrator. It stands up the workflow's org/space,
+// pushes its app, and maps its route.
+func (o *orchestrator) Setup(runner cmdRunner.CmdRunner, cmdGenerator cfCmdGenerator.CfCmdGenerator) error {
+	cmds := append(o.workflow.Setup(cmdGenerator), o.workflow.Push(cmdGenerator)...)
+	cmds = append(cmds, o.workflow.MapRoute(cmdGenerator)...)
+
+	return runner.RunInSequence(cmds...)
+}
+
+// Run implements Orchestrator. If performMeasurements is true, every
+// measurement is started before the `while` commands run and stopped
+// once they finish. Canceling ctx ends the `while` commands early so a
+// killed run still falls through to TearDown.
+func (o *orchestrator) Run(ctx context.Context, performMeasurements bool) (int, error) {
+	if performMeasurements {
+		for _, m := range o.measurements {
+			m.Start(ctx)
+		}
+		defer func() {
+			for _, m := range o.measurements {
+				m.Stop()
+			}
+		}()
+	}
+
+	return o.runWhileCommands(ctx)
+}
+
+// TearDown implements Orchestrator. It deletes the workflow's app, org,
+// and quota.
+func (o *orchestrator) TearDown(runner cmdRunner.CmdRunner, cmdGenerator cfCmdGenerator.CfCmdGenerator) error {
+	cmds := append(o.workflow.Delete(cmdGenerator), o.workflow.TearDown(cmdGenerator)...)
+
+	return runner.RunInSequence(cmds...)
+}
+
+func (o *orchestrator) runWhileCommands(ctx context.Context) (int, error) {
+	for _, c := range o.whileCommands {
+		cmd := exec.CommandContext(ctx, c.Command, c.CommandArgs...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				return exitErr.ExitCode(), nil
+			}
+
+			return 1, err
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	"github.com/cloudfoundry/uptimer/config"
+	"github.com/cloudfoundry/uptimer/logger"
+	"github.com/cloudfoundry/uptimer/measurement"
+)
+
+type nullLogger struct{}
+
+func (nullLogger) Debug(msg string)            {}
+func (nullLogger) Info(msg string)             {}
+func (nullLogger) Error(msg string, err error) {}
+func (l nullLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	return l
+}
+
+type fakeWorkflow struct {
+	setupCmds, pushCmds, deleteCmds, mapRouteCmds, tearDownCmds int
+}
+
+func (f *fakeWorkflow) cmds(n int) []cmdStartWaiter.CmdStartWaiter {
+	return make([]cmdStartWaiter.CmdStartWaiter, n)
+}
+
+func (f *fakeWorkflow) Setup(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return f.cmds(f.setupCmds)
+}
+func (f *fakeWorkflow) Push(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return f.cmds(f.pushCmds)
+}
+func (f *fakeWorkflow) Delete(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return f.cmds(f.deleteCmds)
+}
+func (f *fakeWorkflow) MapRoute(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return f.cmds(f.mapRouteCmds)
+}
+func (f *fakeWorkflow) TearDown(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return f.cmds(f.tearDownCmds)
+}
+func (f *fakeWorkflow) RecentLogs(cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return nil
+}
+func (f *fakeWorkflow) StreamLogs(context.Context, cfCmdGenerator.CfCmdGenerator) []cmdStartWaiter.CmdStartWaiter {
+	return nil
+}
+func (f *fakeWorkflow) CreateAndBindSyslogDrain(string) []cmdStartWaiter.CmdStartWaiter {
+	return nil
+}
+func (f *fakeWorkflow) RestageApp() []cmdStartWaiter.CmdStartWaiter { return nil }
+func (f *fakeWorkflow) EmitLog(cfCmdGenerator.CfCmdGenerator, string) []cmdStartWaiter.CmdStartWaiter {
+	return nil
+}
+func (f *fakeWorkflow) AppUrl() string { return "https://fake.example.com" }
+
+type fakeRunner struct {
+	received int
+	err      error
+}
+
+func (f *fakeRunner) RunInSequence(cmds ...cmdStartWaiter.CmdStartWaiter) error {
+	f.received += len(cmds)
+	return f.err
+}
+
+type fakeMeasurement struct {
+	started, stopped int
+}
+
+func (f *fakeMeasurement) Start(ctx context.Context) { f.started++ }
+func (f *fakeMeasurement) Stop()                     { f.stopped++ }
+
+func TestSetupRunsSetupPushAndMapRouteInOneSequence(t *testing.T) {
+	workflow := &fakeWorkflow{setupCmds: 2, pushCmds: 1, mapRouteCmds: 1}
+	o := New(nil, nullLogger{}, workflow, nil)
+	runner := &fakeRunner{}
+
+	if err := o.Setup(runner, cfCmdGenerator.New("")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runner.received != 4 {
+		t.Errorf("expected 4 commands run, got %d", runner.received)
+	}
+}
+
+func TestTearDownRunsDeleteThenTearDown(t *testing.T) {
+	workflow := &fakeWorkflow{deleteCmds: 1, tearDownCmds: 2}
+	o := New(nil, nullLogger{}, workflow, nil)
+	runner := &fakeRunner{}
+
+	if err := o.TearDown(runner, cfCmdGenerator.New("")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runner.received != 3 {
+		t.Errorf("expected 3 commands run, got %d", runner.received)
+	}
+}
+
+func TestSetupPropagatesRunnerError(t *testing.T) {
+	workflow := &fakeWorkflow{setupCmds: 1}
+	o := New(nil, nullLogger{}, workflow, nil)
+	wantErr := errors.New("boom")
+
+	if err := o.Setup(&fakeRunner{err: wantErr}, cfCmdGenerator.New("")); err != wantErr {
+		t.Errorf("expected %v, got %v", wantErr, err)
+	}
+}
+
+func TestRunStartsAndStopsMeasurementsWhenRequested(t *testing.T) {
+	m := &fakeMeasurement{}
+	o := New(nil, nullLogger{}, &fakeWorkflow{}, []measurement.Measurement{m})
+
+	exitCode, err := o.Run(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	if m.started != 1 || m.stopped != 1 {
+		t.Errorf("expected measurement to be started and stopped once each, got started=%d stopped=%d", m.started, m.stopped)
+	}
+}
+
+func TestRunSkipsMeasurementsWhenNotRequested(t *testing.T) {
+	m := &fakeMeasurement{}
+	o := New(nil, nullLogger{}, &fakeWorkflow{}, []measurement.Measurement{m})
+
+	if _, err := o.Run(context.Background(), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.started != 0 || m.stopped != 0 {
+		t.Errorf("expected no measurement calls, got started=%d stopped=%d", m.started, m.stopped)
+	}
+}
+
+func TestRunReturnsTheWhileCommandsExitCode(t *testing.T) {
+	o := New([]*config.Command{{Command: "sh", CommandArgs: []string{"-c", "exit 3"}}}, nullLogger{}, &fakeWorkflow{}, nil)
+
+	exitCode, err := o.Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", exitCode)
+	}
+}
+
+func TestRunEndsEarlyWhenContextIsCanceled(t *testing.T) {
+	o := New([]*config.Command{{Command: "sleep", CommandArgs: []string{"5"}}}, nullLogger{}, &fakeWorkflow{}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		o.Run(ctx, false)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a canceled context to end the while commands immediately")
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/config"
+	"github.com/cloudfoundry/uptimer/logger"
+)
+
+// runListOrphans queries the CF API for uptimer-prefixed orgs and prints
+// them, without deleting anything.
+func runListOrphans(c *cli.Context, log logger.Logger) error {
+	configPath := c.String("configFile")
+	if configPath == "" {
+		return cli.Exit(fmt.Errorf("'--configFile' flag required"), 1)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("failed to load config: %w", err), 1)
+	}
+	config.Override(cfg, c)
+
+	tmpDir, err := ioutil.TempDir("", "uptimer-list-orphans")
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	cmdGenerator := cfCmdGenerator.New(tmpDir)
+	shutdownTimeout, err := time.ParseDuration(cfg.ShutdownTimeout)
+	if err != nil {
+		shutdownTimeout = 60 * time.Second
+	}
+	runner, outBuf, errBuf := createBufferedRunner(shutdownTimeout)
+
+	if err := runner.RunInSequence(cmdGenerator.Api(cfg.CF.API, true), cmdGenerator.Auth(cfg.CF.AdminUser, cfg.CF.AdminPassword)); err != nil {
+		logBufferedRunnerFailure(log, "login", err, outBuf, errBuf)
+		return cli.Exit("failed to log in to CF", 1)
+	}
+
+	orgs, err := findOrgsWithPrefix(cmdGenerator, runner, outBuf, errBuf, orphanOrgPrefix)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if len(orgs) == 0 {
+		log.Info("No orphaned uptimer orgs found")
+		return nil
+	}
+
+	for _, org := range orgs {
+		fmt.Println(org)
+	}
+
+	return nil
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/urfave/cli/v2"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/cloudfoundry/uptimer/appLogValidator"
+	"github.com/cloudfoundry/uptimer/artifacts"
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cfWorkflow"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/cmdStartWaiter"
+	"github.com/cloudfoundry/uptimer/config"
+	"github.com/cloudfoundry/uptimer/logger"
+	"github.com/cloudfoundry/uptimer/measurement"
+	"github.com/cloudfoundry/uptimer/orchestrator"
+)
+
+func runRun(c *cli.Context, log logger.Logger) error {
+	configPath := c.String("configFile")
+	if configPath == "" {
+		return cli.Exit(fmt.Errorf("'--configFile' flag required"), 1)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("failed to load config: %w", err), 1)
+	}
+	config.Override(cfg, c)
+
+	shutdownTimeout, err := time.ParseDuration(cfg.ShutdownTimeout)
+	if err != nil {
+		shutdownTimeout = 60 * time.Second
+	}
+
+	ctx, cancel := installSignalHandler(log)
+	defer cancel()
+
+	performMeasurements := true
+
+	log.Info("Building included app...")
+	appPath, err := compileIncludedApp("app")
+	if err != nil {
+		log.Error("Failed to build included app", err)
+		performMeasurements = false
+	}
+	log.Info("Finished building included app")
+
+	log.Info("Building included syslog sink app...")
+	sinkAppPath, err := compileIncludedApp("syslogSink")
+	if err != nil {
+		log.Error("Failed to build included syslog sink app", err)
+	}
+	log.Info("Finished building included syslog sink app")
+
+	orcTmpDir, recentLogsTmpDir, streamingLogsTmpDir, pushTmpDir, sinkTmpDir, syslogDrainTmpDir, err := createTmpDirs()
+	if err != nil {
+		log.Error("Failed to create temp dir", err)
+		performMeasurements = false
+	}
+
+	artifactsDir, err := ioutil.TempDir("", "uptimer-artifacts")
+	if err != nil {
+		log.Error("Failed to create artifacts dir", err)
+	}
+
+	artifactsManager, err := newArtifactsManager(cfg.Artifacts, artifactsDir)
+	if err != nil {
+		log.Error("Failed to set up artifacts uploader", err)
+	}
+	if artifactsManager != nil {
+		artifactsManager.Start(context.Background())
+	}
+
+	bufferedRunner, runnerOutBuf, runnerErrBuf := createBufferedRunner(shutdownTimeout)
+
+	pushCmdGenerator := cfCmdGenerator.New(pushTmpDir)
+	pushWorkflow, pushOrg, _ := createWorkflow(cfg.CF, appPath, "./app")
+	log.WithFields(map[string]interface{}{"org": pushOrg}).Info("Setting up push workflow...")
+	if err := bufferedRunner.RunInSequence(pushWorkflow.Setup(pushCmdGenerator)...); err != nil {
+		logBufferedRunnerFailure(log, "push workflow setup", err, runnerOutBuf, runnerErrBuf)
+		performMeasurements = false
+	} else {
+		log.Info("Finished setting up push workflow")
+	}
+
+	sinkCmdGenerator := cfCmdGenerator.New(sinkTmpDir)
+	sinkWorkflow, sinkOrg, _ := createWorkflow(cfg.CF, sinkAppPath, "./syslogSink")
+	log.WithFields(map[string]interface{}{"org": sinkOrg}).Info("Setting up sink workflow...")
+	err = bufferedRunner.RunInSequence(
+		append(append(
+			sinkWorkflow.Setup(sinkCmdGenerator),
+			sinkWorkflow.Push(sinkCmdGenerator)...),
+			sinkWorkflow.MapRoute(sinkCmdGenerator)...)...)
+	if err != nil {
+		logBufferedRunnerFailure(log, "sink workflow setup", err, runnerOutBuf, runnerErrBuf)
+		performMeasurements = false
+	} else {
+		log.Info("Finished setting up sink workflow")
+	}
+
+	orcCmdGenerator := cfCmdGenerator.New(orcTmpDir)
+	orcWorkflow, orcOrg, _ := createWorkflow(cfg.CF, appPath, "./app")
+
+	promExporter := measurement.NewPrometheusExporter()
+	if cfg.PrometheusPort != 0 {
+		log.WithFields(map[string]interface{}{"port": cfg.PrometheusPort}).Info("Starting Prometheus metrics endpoint...")
+		if err := promExporter.Start(cfg.PrometheusPort); err != nil {
+			log.Error("Failed to start Prometheus metrics endpoint", err)
+		}
+	}
+
+	sinkRoute := strings.TrimPrefix(sinkWorkflow.AppUrl(), "https://")
+	log.WithFields(map[string]interface{}{"route": sinkRoute}).Info("Creating and binding syslog drain...")
+	if err := bufferedRunner.RunInSequence(orcWorkflow.CreateAndBindSyslogDrain(sinkRoute)...); err != nil {
+		logBufferedRunnerFailure(log, "create and bind syslog drain", err, runnerOutBuf, runnerErrBuf)
+		performMeasurements = false
+	} else if err := bufferedRunner.RunInSequence(orcWorkflow.RestageApp()...); err != nil {
+		logBufferedRunnerFailure(log, "restage app", err, runnerOutBuf, runnerErrBuf)
+		performMeasurements = false
+	} else {
+		log.Info("Finished binding syslog drain and restaging app")
+	}
+
+	measurements := createMeasurements(
+		log,
+		orcWorkflow,
+		pushWorkflow,
+		sinkWorkflow,
+		cfCmdGenerator.New(recentLogsTmpDir),
+		cfCmdGenerator.New(streamingLogsTmpDir),
+		cfCmdGenerator.New(syslogDrainTmpDir),
+		cfCmdGenerator.New(syslogDrainTmpDir),
+		pushCmdGenerator,
+		cfg.AllowedFailures,
+		promExporter,
+		shutdownTimeout,
+	)
+
+	orc := orchestrator.New(cfg.While, log, orcWorkflow, measurements)
+
+	log.WithFields(map[string]interface{}{"org": orcOrg}).Info("Setting up main workflow...")
+	if err := orc.Setup(bufferedRunner, orcCmdGenerator); err != nil {
+		logBufferedRunnerFailure(log, "main workflow setup", err, runnerOutBuf, runnerErrBuf)
+		performMeasurements = false
+	} else {
+		log.Info("Finished setting up main workflow")
+	}
+
+	exitCode, err := orc.Run(ctx, performMeasurements)
+	if err != nil {
+		log.Error("Failed run", err)
+	}
+
+	log.Info("Tearing down...")
+	tearDown(
+		orc,
+		orcCmdGenerator,
+		log,
+		pushWorkflow,
+		pushCmdGenerator,
+		bufferedRunner,
+		runnerOutBuf,
+		runnerErrBuf,
+	)
+	if err := promExporter.Stop(); err != nil {
+		log.Error("Failed to stop Prometheus metrics endpoint", err)
+	}
+
+	if artifactsManager != nil {
+		artifactsManager.Stop()
+		if err := writeFinalArtifacts(artifactsDir, runnerOutBuf, runnerErrBuf); err != nil {
+			log.Error("Failed to write final artifacts", err)
+		}
+		if err := artifactsManager.FlushAll(context.Background()); err != nil {
+			log.Error("Failed to upload final artifacts", err)
+		}
+	}
+
+	log.Info("Finished tearing down")
+
+	if exitCode != 0 {
+		return cli.Exit("", exitCode)
+	}
+
+	return nil
+}
+
+func newArtifactsManager(cfg *config.Artifacts, rootDir string) (*artifacts.Manager, error) {
+	if cfg == nil || cfg.Bucket == "" {
+		return nil, nil
+	}
+
+	sweepInterval, err := time.ParseDuration(cfg.SweepInterval)
+	if err != nil {
+		sweepInterval = time.Minute
+	}
+
+	var uploader artifacts.Uploader
+	switch cfg.Provider {
+	case "gcs":
+		uploader, err = artifacts.NewGCSUploader(context.Background(), cfg.Bucket, cfg.Prefix)
+	case "local":
+		uploader, err = artifacts.NewLocalUploader(cfg.Bucket)
+	default:
+		uploader, err = artifacts.NewS3Uploader(cfg.Bucket, cfg.Region, cfg.Prefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return artifacts.NewManager(rootDir, uploader, sweepInterval, 4), nil
+}
+
+func writeFinalArtifacts(dir string, runnerOutBuf, runnerErrBuf *bytes.Buffer) error {
+	if err := ioutil.WriteFile(path.Join(dir, "stdout.log"), runnerOutBuf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path.Join(dir, "stderr.log"), runnerErrBuf.Bytes(), 0644)
+}
+
+func loadConfig(configPath string) (*config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func createTmpDirs() (string, string, string, string, string, string, error) {
+	orcTmpDir, err := ioutil.TempDir("", "uptimer")
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+	recentLogsTmpDir, err := ioutil.TempDir("", "uptimer")
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+	streamingLogsTmpDir, err := ioutil.TempDir("", "uptimer")
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+	pushTmpDir, err := ioutil.TempDir("", "uptimer")
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+	sinkTmpDir, err := ioutil.TempDir("", "uptimer")
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+	syslogDrainTmpDir, err := ioutil.TempDir("", "uptimer")
+	if err != nil {
+		return "", "", "", "", "", "", err
+	}
+
+	return orcTmpDir, recentLogsTmpDir, streamingLogsTmpDir, pushTmpDir, sinkTmpDir, syslogDrainTmpDir, nil
+}
+
+func compileIncludedApp(appName string) (string, error) {
+	appPath := path.Join(
+		os.Getenv("GOPATH"),
+		fmt.Sprintf("/src/github.com/cloudfoundry/uptimer/%s", appName),
+	)
+
+	buildCmd := exec.Command("go", "build")
+	buildCmd.Dir = appPath
+	buildCmd.Env = []string{
+		"GOOS=linux",
+		"GOARCH=amd64",
+		fmt.Sprintf("GOPATH=%s", os.Getenv("GOPATH")),
+	}
+	err := buildCmd.Run()
+
+	return appPath, err
+}
+
+func createWorkflow(cfc *config.Cf, appPath, appCommand string) (cfWorkflow.CfWorkflow, string, string) {
+	org := fmt.Sprintf("uptimer-org-%s", uuid.NewV4().String())
+	app := fmt.Sprintf("uptimer-app-%s", uuid.NewV4().String())
+
+	return cfWorkflow.New(
+			cfc,
+			org,
+			fmt.Sprintf("uptimer-space-%s", uuid.NewV4().String()),
+			fmt.Sprintf("uptimer-quota-%s", uuid.NewV4().String()),
+			app,
+			appPath,
+			appCommand,
+		),
+		org,
+		app
+}
+
+func createMeasurements(
+	log logger.Logger,
+	orcWorkflow, pushWorkflow, sinkWorkflow cfWorkflow.CfWorkflow,
+	recentLogsCmdGenerator, streamingLogsCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	syslogDrainEmitCmdGenerator, syslogDrainRecentLogsCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	pushCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	allowedFailures config.AllowedFailures,
+	promExporter *measurement.PrometheusExporter,
+	shutdownTimeout time.Duration,
+) []measurement.Measurement {
+	recentLogsBufferRunner, recentLogsRunnerOutBuf, recentLogsRunnerErrBuf := createBufferedRunner(shutdownTimeout)
+	recentLogsMeasurement := measurement.NewRecentLogs(
+		func() []cmdStartWaiter.CmdStartWaiter {
+			return orcWorkflow.RecentLogs(recentLogsCmdGenerator)
+		},
+		recentLogsBufferRunner,
+		recentLogsRunnerOutBuf,
+		recentLogsRunnerErrBuf,
+		appLogValidator.New(),
+	)
+
+	streamingLogsBufferRunner, streamingLogsRunnerOutBuf, streamingLogsRunnerErrBuf := createBufferedRunner(shutdownTimeout)
+	streamingLogsMeasurement := measurement.NewStreamingLogs(
+		func() (context.Context, context.CancelFunc, []cmdStartWaiter.CmdStartWaiter) {
+			ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
+			return ctx, cancelFunc, orcWorkflow.StreamLogs(ctx, streamingLogsCmdGenerator)
+		},
+		streamingLogsBufferRunner,
+		streamingLogsRunnerOutBuf,
+		streamingLogsRunnerErrBuf,
+		appLogValidator.New(),
+	)
+
+	pushRunner, pushRunnerOutBuf, pushRunnerErrBuf := createBufferedRunner(shutdownTimeout)
+	appPushabilityMeasurement := measurement.NewAppPushability(
+		func() []cmdStartWaiter.CmdStartWaiter {
+			return append(pushWorkflow.Push(pushCmdGenerator), pushWorkflow.Delete(pushCmdGenerator)...)
+		},
+		pushRunner,
+		pushRunnerOutBuf,
+		pushRunnerErrBuf,
+	)
+
+	syslogDrainRunner, syslogDrainRunnerOutBuf, syslogDrainRunnerErrBuf := createBufferedRunner(shutdownTimeout)
+	syslogDrainMeasurement := measurement.NewSyslogDrain(
+		func(tag string) []cmdStartWaiter.CmdStartWaiter {
+			return orcWorkflow.EmitLog(syslogDrainEmitCmdGenerator, tag)
+		},
+		func() []cmdStartWaiter.CmdStartWaiter {
+			return sinkWorkflow.RecentLogs(syslogDrainRecentLogsCmdGenerator)
+		},
+		syslogDrainRunner,
+		syslogDrainRunnerOutBuf,
+		syslogDrainRunnerErrBuf,
+		appLogValidator.New(),
+	)
+
+	httpAvailabilityMeasurement := measurement.NewHTTPAvailability(
+		orcWorkflow.AppUrl(),
+		&http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+		promExporter,
+	)
+
+	authFailedRetryFunc := func(stdOut, stdErr string) bool {
+		authFailedMessage := "Authentication has expired.  Please log back in to re-authenticate."
+		return strings.Contains(stdOut, authFailedMessage) || strings.Contains(stdErr, authFailedMessage)
+	}
+
+	clock := clock.New()
+	return []measurement.Measurement{
+		measurement.NewPeriodic(
+			log,
+			clock,
+			time.Second,
+			httpAvailabilityMeasurement,
+			measurement.NewResultSet(),
+			allowedFailures.HttpAvailability,
+			func(string, string) bool { return false },
+			promExporter,
+		),
+		measurement.NewPeriodic(
+			log,
+			clock,
+			time.Minute,
+			appPushabilityMeasurement,
+			measurement.NewResultSet(),
+			allowedFailures.AppPushability,
+			authFailedRetryFunc,
+			promExporter,
+		),
+		measurement.NewPeriodic(
+			log,
+			clock,
+			10*time.Second,
+			recentLogsMeasurement,
+			measurement.NewResultSet(),
+			allowedFailures.RecentLogs,
+			authFailedRetryFunc,
+			promExporter,
+		),
+		measurement.NewPeriodic(
+			log,
+			clock,
+			30*time.Second,
+			streamingLogsMeasurement,
+			measurement.NewResultSet(),
+			allowedFailures.StreamingLogs,
+			authFailedRetryFunc,
+			promExporter,
+		),
+		measurement.NewPeriodic(
+			log,
+			clock,
+			30*time.Second,
+			syslogDrainMeasurement,
+			measurement.NewResultSet(),
+			allowedFailures.SyslogDrain,
+			authFailedRetryFunc,
+			promExporter,
+		),
+	}
+}
+
+// createBufferedRunner builds a CmdRunner that buffers a command's
+// stdout/stderr for inclusion in a failure log, killing the command if
+// it hasn't finished within timeout so a hung `cf` invocation can't
+// block shutdown indefinitely.
+func createBufferedRunner(timeout time.Duration) (cmdRunner.CmdRunner, *bytes.Buffer, *bytes.Buffer) {
+	outBuf := bytes.NewBuffer([]byte{})
+	errBuf := bytes.NewBuffer([]byte{})
+
+	return cmdRunner.NewWithTimeout(outBuf, errBuf, io.Copy, timeout), outBuf, errBuf
+}
+
+func logBufferedRunnerFailure(
+	log logger.Logger,
+	whatFailed string,
+	err error,
+	outBuf, errBuf *bytes.Buffer,
+) {
+	log.WithFields(map[string]interface{}{
+		"what_failed": whatFailed,
+		"stdout":      outBuf.String(),
+		"stderr":      errBuf.String(),
+	}).Error("Failed "+whatFailed, err)
+	outBuf.Reset()
+	errBuf.Reset()
+}
+
+func tearDown(
+	orc orchestrator.Orchestrator,
+	orcCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	log logger.Logger,
+	pushWorkflow cfWorkflow.CfWorkflow,
+	pushCmdGenerator cfCmdGenerator.CfCmdGenerator,
+	runner cmdRunner.CmdRunner,
+	runnerOutBuf *bytes.Buffer,
+	runnerErrBuf *bytes.Buffer,
+) {
+	if err := orc.TearDown(runner, orcCmdGenerator); err != nil {
+		logBufferedRunnerFailure(log, "main teardown", err, runnerOutBuf, runnerErrBuf)
+	}
+
+	if err := runner.RunInSequence(pushWorkflow.TearDown(pushCmdGenerator)...); err != nil {
+		logBufferedRunnerFailure(log, "push workflow teardown", err, runnerOutBuf, runnerErrBuf)
+	}
+}
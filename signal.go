@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cloudfoundry/uptimer/logger"
+)
+
+// installSignalHandler returns a context that is canceled the moment the
+// process receives SIGINT, SIGTERM, or SIGHUP. orc.Run and each
+// measurement.Periodic loop select on ctx.Done() so a killed run still
+// falls through to tearDown instead of leaking orgs, spaces, and quotas.
+func installSignalHandler(log logger.Logger) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+		sig := <-sigCh
+		log.WithFields(map[string]interface{}{"signal": sig.String()}).Info("Received shutdown signal, canceling in-flight work...")
+		cancel()
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cloudfoundry/uptimer/cfCmdGenerator"
+	"github.com/cloudfoundry/uptimer/cfWorkflow"
+	"github.com/cloudfoundry/uptimer/cmdRunner"
+	"github.com/cloudfoundry/uptimer/config"
+	"github.com/cloudfoundry/uptimer/logger"
+)
+
+const orphanOrgPrefix = "uptimer-org-"
+
+// runTeardown deletes any orgs, spaces, and quotas left behind by a run
+// that was killed before it could tear itself down.
+func runTeardown(c *cli.Context, log logger.Logger) error {
+	configPath := c.String("configFile")
+	if configPath == "" {
+		return cli.Exit(fmt.Errorf("'--configFile' flag required"), 1)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("failed to load config: %w", err), 1)
+	}
+	config.Override(cfg, c)
+
+	tmpDir, err := ioutil.TempDir("", "uptimer-teardown")
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	cmdGenerator := cfCmdGenerator.New(tmpDir)
+	shutdownTimeout, err := time.ParseDuration(cfg.ShutdownTimeout)
+	if err != nil {
+		shutdownTimeout = 60 * time.Second
+	}
+	runner, outBuf, errBuf := createBufferedRunner(shutdownTimeout)
+
+	if err := runner.RunInSequence(cmdGenerator.Api(cfg.CF.API, true), cmdGenerator.Auth(cfg.CF.AdminUser, cfg.CF.AdminPassword)); err != nil {
+		logBufferedRunnerFailure(log, "login", err, outBuf, errBuf)
+		return cli.Exit("failed to log in to CF", 1)
+	}
+
+	orgs, err := findOrgsWithPrefix(cmdGenerator, runner, outBuf, errBuf, orphanOrgPrefix)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if len(orgs) == 0 {
+		log.Info("No orphaned uptimer orgs found")
+		return nil
+	}
+
+	for _, org := range orgs {
+		quota, err := findQuotaForOrg(cmdGenerator, runner, outBuf, errBuf, org)
+		if err != nil {
+			logBufferedRunnerFailure(log, fmt.Sprintf("find quota for org %s", org), err, outBuf, errBuf)
+			continue
+		}
+
+		log.WithFields(map[string]interface{}{"org": org, "quota": quota}).Info("Deleting orphaned org and quota...")
+
+		workflow := cfWorkflow.New(cfg.CF, org, "", quota, "", "", "")
+		if err := runner.RunInSequence(workflow.TearDown(cmdGenerator)...); err != nil {
+			logBufferedRunnerFailure(log, fmt.Sprintf("tear down org %s", org), err, outBuf, errBuf)
+		}
+	}
+
+	return nil
+}
+
+func findOrgsWithPrefix(
+	cmdGenerator cfCmdGenerator.CfCmdGenerator,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+	prefix string,
+) ([]string, error) {
+	if err := runner.RunInSequence(cmdGenerator.Orgs()); err != nil {
+		return nil, fmt.Errorf("listing orgs: %w", err)
+	}
+
+	var matches []string
+	for _, line := range strings.Split(outBuf.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, prefix) {
+			matches = append(matches, line)
+		}
+	}
+	outBuf.Reset()
+	errBuf.Reset()
+
+	return matches, nil
+}
+
+// findQuotaForOrg looks up the quota definition assigned to org, by
+// parsing the "quota:" line out of `cf org`'s output. Quota definitions
+// are a distinct CC API object from the org they're assigned to, so
+// `cf delete-org` alone leaves them behind.
+func findQuotaForOrg(
+	cmdGenerator cfCmdGenerator.CfCmdGenerator,
+	runner cmdRunner.CmdRunner,
+	outBuf, errBuf *bytes.Buffer,
+	org string,
+) (string, error) {
+	if err := runner.RunInSequence(cmdGenerator.Org(org)); err != nil {
+		outBuf.Reset()
+		errBuf.Reset()
+
+		return "", fmt.Errorf("inspecting org %s: %w", org, err)
+	}
+
+	quota := ""
+	for _, line := range strings.Split(outBuf.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "quota:") {
+			quota = strings.TrimSpace(strings.TrimPrefix(line, "quota:"))
+			break
+		}
+	}
+	outBuf.Reset()
+	errBuf.Reset()
+
+	if quota == "" {
+		return "", fmt.Errorf("could not determine quota for org %s", org)
+	}
+
+	return quota, nil
+}
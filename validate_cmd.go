@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/cloudfoundry/uptimer/config"
+	"github.com/cloudfoundry/uptimer/logger"
+)
+
+func runValidateConfig(c *cli.Context, log logger.Logger) error {
+	configPath := c.String("configFile")
+	if configPath == "" {
+		return cli.Exit(fmt.Errorf("'--configFile' flag required"), 1)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("failed to load config: %w", err), 1)
+	}
+	config.Override(cfg, c)
+
+	if err := config.Validate(cfg); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	log.Info("Config is valid")
+
+	return nil
+}
@@ -0,0 +1,6 @@
+// Package version holds uptimer's build-time version string.
+package version
+
+// Version is overridden at build time via -ldflags, e.g.:
+//   go build -ldflags "-X github.com/cloudfoundry/uptimer/version.Version=1.2.3"
+var Version = "dev"